@@ -0,0 +1,419 @@
+// Package certmanager provides a long-running certificate renewal manager,
+// comparable to autocert-style managers, built on top of the lego ACME
+// client and certcrypto helpers. It is intended for services that want to
+// keep a set of domains' certificates current without driving the ACME
+// flow themselves.
+package certmanager
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/xenolf/lego/emca/certificate/certcrypto"
+)
+
+// DefaultRenewalWindow is how long before expiration a certificate is renewed.
+const DefaultRenewalWindow = 30 * 24 * time.Hour
+
+// DefaultCheckInterval is how often the Manager checks whether any managed
+// certificate needs renewal.
+const DefaultCheckInterval = time.Hour
+
+// DefaultOCSPRefreshInterval is how often OCSP staples are refreshed for
+// certificates that are not otherwise due for renewal.
+const DefaultOCSPRefreshInterval = 6 * time.Hour
+
+// Hooks are optional callbacks invoked as the Manager does its work. Any
+// field left nil is simply skipped.
+type Hooks struct {
+	// OnCertObtained is called after a certificate is successfully obtained or renewed.
+	OnCertObtained func(cert *Certificate)
+
+	// OnRenewalFailed is called when a renewal attempt fails. The Manager will retry on the next check.
+	OnRenewalFailed func(domains []string, err error)
+
+	// OnOCSPUpdated is called after an OCSP staple is successfully refreshed.
+	OnOCSPUpdated func(cert *Certificate)
+}
+
+// Config configures a Manager.
+type Config struct {
+	Domains             []string
+	Storage             Storage
+	Issuer              Issuer
+	RenewalWindow       time.Duration
+	CheckInterval       time.Duration
+	OCSPRefreshInterval time.Duration
+	Hooks               Hooks
+}
+
+// Manager keeps a set of certificates renewed, holding a distributed lock
+// in Storage during ACME work so multiple lego instances behind a load
+// balancer don't race to renew the same certificate.
+type Manager struct {
+	config *Config
+
+	mu      sync.RWMutex
+	certs   map[string]*Certificate
+	tlsCert *tls.Certificate
+
+	stopOnce sync.Once
+	stopCh   chan struct{}
+}
+
+// NewManager creates a Manager for config.Domains, using config.Issuer to
+// obtain and renew certificates and config.Storage to persist them and
+// coordinate renewal across instances.
+func NewManager(config *Config) (*Manager, error) {
+	if config == nil {
+		return nil, fmt.Errorf("certmanager: the configuration is nil")
+	}
+
+	if config.Storage == nil {
+		return nil, fmt.Errorf("certmanager: a Storage implementation is required")
+	}
+
+	if config.Issuer == nil {
+		return nil, fmt.Errorf("certmanager: an Issuer implementation is required")
+	}
+
+	if len(config.Domains) == 0 {
+		return nil, fmt.Errorf("certmanager: at least one domain is required")
+	}
+
+	if config.RenewalWindow <= 0 {
+		config.RenewalWindow = DefaultRenewalWindow
+	}
+
+	if config.CheckInterval <= 0 {
+		config.CheckInterval = DefaultCheckInterval
+	}
+
+	if config.OCSPRefreshInterval <= 0 {
+		config.OCSPRefreshInterval = DefaultOCSPRefreshInterval
+	}
+
+	return &Manager{
+		config: config,
+		certs:  make(map[string]*Certificate),
+		stopCh: make(chan struct{}),
+	}, nil
+}
+
+// Start begins the renewal and OCSP refresh loops. It blocks until the
+// initial certificate for each configured domain set is obtained or loaded
+// from storage, then returns and continues the work in the background.
+//
+// A certificate loaded from Storage is checked against the renewal window
+// immediately, synchronously, rather than waiting for the first
+// CheckInterval tick: an instance that was stopped for longer than
+// CheckInterval must not serve a stale or expired certificate until the
+// next tick fires.
+func (m *Manager) Start() error {
+	cert, err := m.loadOrObtain()
+	if err != nil {
+		return err
+	}
+
+	if err := m.setCert(cert); err != nil {
+		return fmt.Errorf("certmanager: loaded certificate is invalid: %w", err)
+	}
+
+	m.renewIfDue()
+
+	go m.renewalLoop()
+	go m.ocspLoop()
+
+	return nil
+}
+
+// Stop halts the background renewal and OCSP refresh loops.
+func (m *Manager) Stop() {
+	m.stopOnce.Do(func() {
+		close(m.stopCh)
+	})
+}
+
+// GetCertificate is compatible with tls.Config.GetCertificate. It serves
+// the currently managed certificate.
+//
+// It does not drive the TLS-ALPN-01 challenge: Issuer has no hook for the
+// Manager to learn a challenge is in progress or to obtain the "acme-tls/1"
+// certificate that challenge requires, so a provider that needs TLS-ALPN-01
+// has to be driven separately from the Manager.
+func (m *Manager) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	m.mu.RLock()
+	cert := m.tlsCert
+	m.mu.RUnlock()
+
+	if cert == nil {
+		return nil, fmt.Errorf("certmanager: no certificate available for %s", hello.ServerName)
+	}
+
+	return cert, nil
+}
+
+// HTTPHandler wraps fallback so health checks and load balancers can reach
+// the instance while it holds the renewal lock. It does not serve any
+// challenge responses itself; it simply delegates to fallback, or returns
+// 404 if fallback is nil.
+func (m *Manager) HTTPHandler(fallback http.Handler) http.Handler {
+	if fallback != nil {
+		return fallback
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+}
+
+func (m *Manager) renewalLoop() {
+	ticker := time.NewTicker(m.config.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.renewIfDue()
+		case <-m.stopCh:
+			return
+		}
+	}
+}
+
+func (m *Manager) ocspLoop() {
+	ticker := time.NewTicker(m.config.OCSPRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.refreshOCSP()
+		case <-m.stopCh:
+			return
+		}
+	}
+}
+
+func (m *Manager) renewIfDue() {
+	m.mu.RLock()
+	cert := m.certs[storageKey(m.config.Domains)]
+	m.mu.RUnlock()
+
+	if cert == nil {
+		return
+	}
+
+	expiration, err := certcrypto.GetPEMCertExpiration(cert.Certificate)
+	if err != nil {
+		m.onRenewalFailed(err)
+		return
+	}
+
+	if time.Until(expiration) > m.config.RenewalWindow {
+		return
+	}
+
+	key := storageKey(m.config.Domains)
+	if err := m.config.Storage.Lock(key); err != nil {
+		m.onRenewalFailed(fmt.Errorf("could not acquire renewal lock: %w", err))
+		return
+	}
+	defer m.config.Storage.Unlock(key)
+
+	// Another instance may have renewed this certificate while we were
+	// waiting for the lock, so re-read Storage and check the renewal
+	// window again before calling the CA.
+	if fresh, err := m.loadFresh(key); err != nil {
+		m.onRenewalFailed(err)
+		return
+	} else if fresh != nil {
+		cert = fresh
+		expiration, err := certcrypto.GetPEMCertExpiration(cert.Certificate)
+		if err != nil {
+			m.onRenewalFailed(err)
+			return
+		}
+
+		if time.Until(expiration) > m.config.RenewalWindow {
+			if err := m.setCert(cert); err != nil {
+				m.onRenewalFailed(fmt.Errorf("certificate renewed by another instance is invalid: %w", err))
+			}
+			return
+		}
+	}
+
+	renewed, err := m.config.Issuer.Renew(cert)
+	if err != nil {
+		m.onRenewalFailed(err)
+		return
+	}
+
+	if err := m.persist(renewed); err != nil {
+		m.onRenewalFailed(err)
+		return
+	}
+
+	if err := m.setCert(renewed); err != nil {
+		m.onRenewalFailed(fmt.Errorf("renewed certificate is invalid: %w", err))
+		return
+	}
+
+	if m.config.Hooks.OnCertObtained != nil {
+		m.config.Hooks.OnCertObtained(renewed)
+	}
+}
+
+func (m *Manager) refreshOCSP() {
+	m.mu.RLock()
+	cert := m.certs[storageKey(m.config.Domains)]
+	m.mu.RUnlock()
+
+	if cert == nil {
+		return
+	}
+
+	rawResp, _, err := certcrypto.GetOCSPForCert(cert.Certificate)
+	if err != nil {
+		return
+	}
+
+	cert.OCSPStaple = rawResp
+
+	if err := m.persist(cert); err != nil {
+		return
+	}
+
+	if err := m.setCert(cert); err != nil {
+		m.onRenewalFailed(fmt.Errorf("certificate with refreshed OCSP staple is invalid: %w", err))
+		return
+	}
+
+	if m.config.Hooks.OnOCSPUpdated != nil {
+		m.config.Hooks.OnOCSPUpdated(cert)
+	}
+}
+
+func (m *Manager) loadOrObtain() (*Certificate, error) {
+	key := storageKey(m.config.Domains)
+
+	data, err := m.config.Storage.Get(key)
+	switch {
+	case err == nil:
+		return decodeCertificate(data)
+	case errors.Is(err, ErrNotFound):
+		// Fall through to obtain a fresh certificate below.
+	default:
+		return nil, fmt.Errorf("certmanager: could not load certificate for %s: %w", key, err)
+	}
+
+	if err := m.config.Storage.Lock(key); err != nil {
+		return nil, fmt.Errorf("certmanager: could not acquire lock for %s: %w", key, err)
+	}
+	defer m.config.Storage.Unlock(key)
+
+	// Another instance may have obtained this certificate while we were
+	// waiting for the lock.
+	if fresh, err := m.loadFresh(key); err != nil {
+		return nil, err
+	} else if fresh != nil {
+		return fresh, nil
+	}
+
+	cert, err := m.config.Issuer.Obtain(m.config.Domains)
+	if err != nil {
+		return nil, fmt.Errorf("certmanager: could not obtain certificate for %v: %w", m.config.Domains, err)
+	}
+
+	if err := m.persist(cert); err != nil {
+		return nil, err
+	}
+
+	if m.config.Hooks.OnCertObtained != nil {
+		m.config.Hooks.OnCertObtained(cert)
+	}
+
+	return cert, nil
+}
+
+// loadFresh re-reads key from Storage after the caller has acquired the
+// renewal lock, returning the stored certificate if one now exists, or nil
+// if Storage still reports ErrNotFound. It exists so loadOrObtain and
+// renewIfDue can notice a certificate another instance obtained or renewed
+// while this instance was waiting for the lock, instead of calling the CA
+// again based on the possibly-stale state read before the lock was taken.
+func (m *Manager) loadFresh(key string) (*Certificate, error) {
+	data, err := m.config.Storage.Get(key)
+	switch {
+	case err == nil:
+		cert, err := decodeCertificate(data)
+		if err != nil {
+			return nil, err
+		}
+
+		return cert, nil
+	case errors.Is(err, ErrNotFound):
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("certmanager: could not re-check stored certificate for %s: %w", key, err)
+	}
+}
+
+func (m *Manager) persist(cert *Certificate) error {
+	return m.config.Storage.Put(storageKey(m.config.Domains), encodeCertificate(cert))
+}
+
+// setCert installs cert as the currently served certificate. It returns an
+// error without changing what GetCertificate serves if cert doesn't parse
+// as a valid TLS key pair, so a bad renewal or a corrupt stored certificate
+// can never silently replace a good one.
+func (m *Manager) setCert(cert *Certificate) error {
+	tlsCert, err := tls.X509KeyPair(cert.Certificate, cert.PrivateKey)
+	if err != nil {
+		return fmt.Errorf("certmanager: %w", err)
+	}
+
+	tlsCert.OCSPStaple = cert.OCSPStaple
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.certs[storageKey(m.config.Domains)] = cert
+	m.tlsCert = &tlsCert
+
+	return nil
+}
+
+func (m *Manager) onRenewalFailed(err error) {
+	if m.config.Hooks.OnRenewalFailed != nil {
+		m.config.Hooks.OnRenewalFailed(m.config.Domains, err)
+	}
+}
+
+func encodeCertificate(cert *Certificate) []byte {
+	data, _ := json.Marshal(cert)
+	return data
+}
+
+func decodeCertificate(data []byte) (*Certificate, error) {
+	var cert Certificate
+	if err := json.Unmarshal(data, &cert); err != nil {
+		return nil, fmt.Errorf("certmanager: could not decode stored certificate: %w", err)
+	}
+
+	return &cert, nil
+}
+
+func storageKey(domains []string) string {
+	key := domains[0]
+	for _, domain := range domains[1:] {
+		key += "," + domain
+	}
+
+	return key
+}
@@ -0,0 +1,269 @@
+package certmanager
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+)
+
+type memStorage struct {
+	mu     sync.Mutex
+	data   map[string][]byte
+	locked map[string]bool
+
+	getErr error
+}
+
+func newMemStorage() *memStorage {
+	return &memStorage{data: make(map[string][]byte), locked: make(map[string]bool)}
+}
+
+func (s *memStorage) Get(key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.getErr != nil {
+		return nil, s.getErr
+	}
+
+	data, ok := s.data[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return data, nil
+}
+
+func (s *memStorage) Put(key string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data[key] = data
+	return nil
+}
+
+func (s *memStorage) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.data, key)
+	return nil
+}
+
+func (s *memStorage) Lock(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.locked[key] {
+		return fmt.Errorf("already locked: %s", key)
+	}
+
+	s.locked[key] = true
+	return nil
+}
+
+func (s *memStorage) Unlock(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.locked, key)
+	return nil
+}
+
+type fakeIssuer struct {
+	mu          sync.Mutex
+	obtainCalls int
+	renewCalls  int
+	nextCert    *Certificate
+	obtainErr   error
+	renewErr    error
+}
+
+func (f *fakeIssuer) Obtain(domains []string) (*Certificate, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.obtainCalls++
+	if f.obtainErr != nil {
+		return nil, f.obtainErr
+	}
+
+	return f.nextCert, nil
+}
+
+func (f *fakeIssuer) Renew(cert *Certificate) (*Certificate, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.renewCalls++
+	if f.renewErr != nil {
+		return nil, f.renewErr
+	}
+
+	return f.nextCert, nil
+}
+
+func generateSelfSigned(t *testing.T, notAfter time.Time) *Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+		DNSNames:     []string{"example.com"},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+
+	return &Certificate{
+		Domains:     []string{"example.com"},
+		Certificate: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		PrivateKey:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+func TestStartObtainsWhenStorageIsEmpty(t *testing.T) {
+	storage := newMemStorage()
+	issuer := &fakeIssuer{nextCert: generateSelfSigned(t, time.Now().Add(60*24*time.Hour))}
+
+	m, err := NewManager(&Config{
+		Domains: []string{"example.com"},
+		Storage: storage,
+		Issuer:  issuer,
+	})
+	if err != nil {
+		t.Fatalf("NewManager returned an error: %v", err)
+	}
+	defer m.Stop()
+
+	if err := m.Start(); err != nil {
+		t.Fatalf("Start returned an error: %v", err)
+	}
+
+	if issuer.obtainCalls != 1 {
+		t.Fatalf("expected exactly one Obtain call, got %d", issuer.obtainCalls)
+	}
+
+	if issuer.renewCalls != 0 {
+		t.Fatalf("expected no Renew calls for a fresh certificate, got %d", issuer.renewCalls)
+	}
+}
+
+func TestStartPropagatesStorageErrors(t *testing.T) {
+	storage := newMemStorage()
+	storage.getErr = errors.New("storage unavailable")
+	issuer := &fakeIssuer{nextCert: generateSelfSigned(t, time.Now().Add(60*24*time.Hour))}
+
+	m, err := NewManager(&Config{
+		Domains: []string{"example.com"},
+		Storage: storage,
+		Issuer:  issuer,
+	})
+	if err != nil {
+		t.Fatalf("NewManager returned an error: %v", err)
+	}
+	defer m.Stop()
+
+	if err := m.Start(); err == nil {
+		t.Fatal("expected Start to fail when Storage.Get returns a non-ErrNotFound error")
+	}
+
+	if issuer.obtainCalls != 0 {
+		t.Fatalf("expected Obtain not to be called on a transient storage error, got %d calls", issuer.obtainCalls)
+	}
+}
+
+func TestStartRenewsExpiringCertificateSynchronously(t *testing.T) {
+	storage := newMemStorage()
+	expiring := generateSelfSigned(t, time.Now().Add(time.Hour))
+	storage.data[storageKey([]string{"example.com"})] = encodeCertificate(expiring)
+
+	renewed := generateSelfSigned(t, time.Now().Add(90*24*time.Hour))
+	issuer := &fakeIssuer{nextCert: renewed}
+
+	var obtained []*Certificate
+	m, err := NewManager(&Config{
+		Domains:       []string{"example.com"},
+		Storage:       storage,
+		Issuer:        issuer,
+		RenewalWindow: 30 * 24 * time.Hour,
+		CheckInterval: time.Hour,
+		Hooks: Hooks{
+			OnCertObtained: func(cert *Certificate) { obtained = append(obtained, cert) },
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewManager returned an error: %v", err)
+	}
+	defer m.Stop()
+
+	if err := m.Start(); err != nil {
+		t.Fatalf("Start returned an error: %v", err)
+	}
+
+	if issuer.renewCalls != 1 {
+		t.Fatalf("expected Start to renew the expiring certificate synchronously, got %d Renew calls", issuer.renewCalls)
+	}
+
+	if len(obtained) != 1 {
+		t.Fatalf("expected OnCertObtained to fire once for the renewal, got %d", len(obtained))
+	}
+}
+
+func TestSetCertRejectsInvalidKeyPairWithoutClobberingState(t *testing.T) {
+	storage := newMemStorage()
+	good := generateSelfSigned(t, time.Now().Add(60*24*time.Hour))
+	issuer := &fakeIssuer{nextCert: good}
+
+	m, err := NewManager(&Config{
+		Domains: []string{"example.com"},
+		Storage: storage,
+		Issuer:  issuer,
+	})
+	if err != nil {
+		t.Fatalf("NewManager returned an error: %v", err)
+	}
+	defer m.Stop()
+
+	if err := m.Start(); err != nil {
+		t.Fatalf("Start returned an error: %v", err)
+	}
+
+	broken := &Certificate{Domains: []string{"example.com"}, Certificate: []byte("not a cert"), PrivateKey: []byte("not a key")}
+	if err := m.setCert(broken); err == nil {
+		t.Fatal("expected setCert to reject an invalid key pair")
+	}
+
+	cert, err := m.GetCertificate(&tls.ClientHelloInfo{ServerName: "example.com"})
+	if err != nil {
+		t.Fatalf("expected the last good certificate to still be served: %v", err)
+	}
+
+	if cert == nil {
+		t.Fatal("expected a non-nil certificate")
+	}
+}
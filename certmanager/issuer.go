@@ -0,0 +1,18 @@
+package certmanager
+
+// Certificate is the PEM-encoded material the Manager stores and renews.
+type Certificate struct {
+	Domains     []string
+	PrivateKey  []byte
+	Certificate []byte
+	OCSPStaple  []byte
+}
+
+// Issuer obtains and renews certificates. It is satisfied by a thin wrapper
+// around an emca/old-acme client, kept as an interface here so the Manager
+// can be tested and so callers can plug in their own registration/EAB setup
+// before handing the client to the Manager.
+type Issuer interface {
+	Obtain(domains []string) (*Certificate, error)
+	Renew(cert *Certificate) (*Certificate, error)
+}
@@ -0,0 +1,33 @@
+package certmanager
+
+// Storage persists certificates and coordinates renewal across multiple
+// lego instances behind a load balancer. Implementations are expected to be
+// safe for concurrent use, which is why Lock/Unlock are part of the
+// interface rather than left to the caller: a filesystem implementation can
+// use flock, an S3 implementation can use a lease object, and a distributed
+// KV implementation (etcd, Consul, ...) can use its native lock primitive.
+type Storage interface {
+	// Get returns the data stored under key, or ErrNotFound if it doesn't exist.
+	Get(key string) ([]byte, error)
+
+	// Put stores data under key, overwriting any previous value.
+	Put(key string, data []byte) error
+
+	// Delete removes the data stored under key. It is not an error to
+	// delete a key that doesn't exist.
+	Delete(key string) error
+
+	// Lock acquires a distributed lock identified by key, blocking until
+	// it is available. The lock must be released with Unlock.
+	Lock(key string) error
+
+	// Unlock releases a lock previously acquired with Lock.
+	Unlock(key string) error
+}
+
+// ErrNotFound is returned by Storage.Get when the requested key doesn't exist.
+var ErrNotFound = storageError("certmanager: key not found")
+
+type storageError string
+
+func (e storageError) Error() string { return string(e) }
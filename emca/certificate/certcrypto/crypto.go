@@ -1,6 +1,7 @@
 package certcrypto
 
 import (
+	"bytes"
 	"crypto"
 	"crypto/ecdsa"
 	"crypto/elliptic"
@@ -12,12 +13,25 @@ import (
 	"encoding/pem"
 	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"math/big"
+	"net/http"
 	"time"
 
 	"golang.org/x/crypto/ocsp"
 )
 
+// maxOCSPBodySize is the cap applied when reading an OCSP response or an
+// issuer certificate over HTTP, to avoid a malicious or misbehaving
+// responder exhausting memory.
+const maxOCSPBodySize = 1024 * 1024 // 1 MiB
+
+// OCSPClient is the HTTP client used by GetOCSPForCert to fetch issuer
+// certificates and query OCSP responders. It can be overridden, e.g. to
+// pin TLS certificates or set a custom timeout.
+var OCSPClient = http.DefaultClient
+
 // Constants for all key types we support.
 const (
 	EC256   = KeyType("P256")
@@ -49,80 +63,89 @@ type KeyType string
 
 type DERCertificateBytes []byte
 
-// FIXME move to Client?
 // GetOCSPForCert takes a PEM encoded cert or cert bundle returning the raw OCSP response,
 // the parsed response, and an error, if any.
 // The returned []byte can be passed directly into the OCSPStaple property of a tls.Certificate.
 // If the bundle only contains the issued certificate,
 // this function will try to get the issuer certificate from the IssuingCertificateURL in the certificate.
 // If the []byte and/or ocsp.Response return values are nil, the OCSP status may be assumed OCSPUnknown.
-// func GetOCSPForCert(bundle []byte) ([]byte, *ocsp.Response, error) {
-// 	certificates, err := ParsePEMBundle(bundle)
-// 	if err != nil {
-// 		return nil, nil, err
-// 	}
-//
-// 	// We expect the certificate slice to be ordered downwards the chain.
-// 	// SRV CRT -> CA. We need to pull the leaf and issuer certs out of it,
-// 	// which should always be the first two certificates. If there's no
-// 	// OCSP server listed in the leaf cert, there's nothing to do. And if
-// 	// we have only one certificate so far, we need to get the issuer cert.
-// 	issuedCert := certificates[0]
-// 	if len(issuedCert.OCSPServer) == 0 {
-// 		return nil, nil, errors.New("no OCSP server specified in cert")
-// 	}
-// 	if len(certificates) == 1 {
-// 		// TODO: build fallback. If this fails, check the remaining array entries.
-// 		if len(issuedCert.IssuingCertificateURL) == 0 {
-// 			return nil, nil, errors.New("no issuing certificate URL")
-// 		}
-//
-// 		resp, errC := httpGet(issuedCert.IssuingCertificateURL[0])
-// 		if errC != nil {
-// 			return nil, nil, errC
-// 		}
-// 		defer resp.Body.Close()
-//
-// 		issuerBytes, errC := ioutil.ReadAll(limitReader(resp.Body, maxBodySize))
-// 		if errC != nil {
-// 			return nil, nil, errC
-// 		}
-//
-// 		issuerCert, errC := x509.ParseCertificate(issuerBytes)
-// 		if errC != nil {
-// 			return nil, nil, errC
-// 		}
-//
-// 		// Insert it into the slice on position 0
-// 		// We want it ordered right SRV CRT -> CA
-// 		certificates = append(certificates, issuerCert)
-// 	}
-// 	issuerCert := certificates[1]
-//
-// 	// Finally kick off the OCSP request.
-// 	ocspReq, err := ocsp.CreateRequest(issuedCert, issuerCert, nil)
-// 	if err != nil {
-// 		return nil, nil, err
-// 	}
-//
-// 	resp, err := httpPost(issuedCert.OCSPServer[0], "application/ocsp-request", bytes.NewReader(ocspReq))
-// 	if err != nil {
-// 		return nil, nil, err
-// 	}
-// 	defer resp.Body.Close()
-//
-// 	ocspResBytes, err := ioutil.ReadAll(limitReader(resp.Body, maxBodySize))
-// 	if err != nil {
-// 		return nil, nil, err
-// 	}
-//
-// 	ocspRes, err := ocsp.ParseResponse(ocspResBytes, issuerCert)
-// 	if err != nil {
-// 		return nil, nil, err
-// 	}
-//
-// 	return ocspResBytes, ocspRes, nil
-// }
+func GetOCSPForCert(bundle []byte) ([]byte, *ocsp.Response, error) {
+	certificates, err := ParsePEMBundle(bundle)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// We expect the certificate slice to be ordered downwards the chain.
+	// SRV CRT -> CA. We need to pull the leaf and issuer certs out of it,
+	// which should always be the first two certificates. If there's no
+	// OCSP server listed in the leaf cert, there's nothing to do. And if
+	// we have only one certificate so far, we need to get the issuer cert.
+	issuedCert := certificates[0]
+	if len(issuedCert.OCSPServer) == 0 {
+		return nil, nil, errors.New("no OCSP server specified in cert")
+	}
+	if len(certificates) == 1 {
+		// TODO: build fallback. If this fails, check the remaining array entries.
+		if len(issuedCert.IssuingCertificateURL) == 0 {
+			return nil, nil, errors.New("no issuing certificate URL")
+		}
+
+		issuerCert, errC := fetchIssuerCertificate(issuedCert.IssuingCertificateURL[0])
+		if errC != nil {
+			return nil, nil, errC
+		}
+
+		// Insert it into the slice.
+		// We want it ordered right SRV CRT -> CA.
+		certificates = append(certificates, issuerCert)
+	}
+	issuerCert := certificates[1]
+
+	// Finally kick off the OCSP request.
+	ocspReq, err := ocsp.CreateRequest(issuedCert, issuerCert, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resp, err := OCSPClient.Post(issuedCert.OCSPServer[0], "application/ocsp-request", bytes.NewReader(ocspReq))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	ocspResBytes, err := ioutil.ReadAll(limitOCSPBody(resp.Body))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ocspRes, err := ocsp.ParseResponse(ocspResBytes, issuerCert)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return ocspResBytes, ocspRes, nil
+}
+
+func fetchIssuerCertificate(url string) (*x509.Certificate, error) {
+	resp, err := OCSPClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	issuerBytes, err := ioutil.ReadAll(limitOCSPBody(resp.Body))
+	if err != nil {
+		return nil, err
+	}
+
+	return x509.ParseCertificate(issuerBytes)
+}
+
+// limitOCSPBody caps the number of bytes read from an OCSP responder or issuer URL,
+// to protect against a malicious or misbehaving server.
+func limitOCSPBody(r io.Reader) io.Reader {
+	return io.LimitReader(r, maxOCSPBodySize)
+}
 
 // ParsePEMBundle parses a certificate bundle from top to bottom and returns
 // a slice of x509 certificates. This function will error if no certificates are found.
@@ -183,6 +206,22 @@ func GeneratePrivateKey(keyType KeyType) (crypto.PrivateKey, error) {
 }
 
 func GenerateCsr(privateKey crypto.PrivateKey, domain string, san []string, mustStaple bool) ([]byte, error) {
+	return GenerateCsrWithOptions(privateKey, domain, san, mustStaple, false)
+}
+
+// GenerateCsrWithOptions builds a PEM-less, DER-encoded certificate request
+// for domain, covering the additional names in san.
+//
+// domain may be empty as long as san is not: some CAs (e.g. Let's Encrypt)
+// are happy to issue for a CSR with no CommonName as long as it carries at
+// least one SAN, but others (e.g. some ZeroSSL tiers) reject a CN-less CSR
+// outright. Set synthesizeCNFromSAN to fall back to the first SAN as the
+// CommonName in that case, rather than leaving it empty.
+func GenerateCsrWithOptions(privateKey crypto.PrivateKey, domain string, san []string, mustStaple, synthesizeCNFromSAN bool) ([]byte, error) {
+	if domain == "" && synthesizeCNFromSAN && len(san) > 0 {
+		domain = san[0]
+	}
+
 	template := x509.CertificateRequest{
 		Subject: pkix.Name{CommonName: domain},
 	}
@@ -297,4 +336,4 @@ func generateDerCert(privKey *rsa.PrivateKey, expiration time.Time, domain strin
 	}
 
 	return x509.CreateCertificate(rand.Reader, &template, &template, &privKey.PublicKey, privKey)
-}
\ No newline at end of file
+}
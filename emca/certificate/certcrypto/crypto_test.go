@@ -0,0 +1,182 @@
+package certcrypto
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+func TestGetOCSPForCert(t *testing.T) {
+	issuerKey, issuerCert, issuerDER := generateTestCA(t)
+
+	responder := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		template := ocsp.Response{
+			Status:       ocsp.Good,
+			SerialNumber: big.NewInt(1),
+			ThisUpdate:   time.Now(),
+			NextUpdate:   time.Now().Add(time.Hour),
+		}
+
+		respBytes, err := ocsp.CreateResponse(issuerCert, issuerCert, template, issuerKey)
+		if err != nil {
+			t.Fatalf("failed to create fake OCSP response: %v", err)
+		}
+
+		w.Header().Set("Content-Type", "application/ocsp-response")
+		_, _ = w.Write(respBytes)
+	}))
+	defer responder.Close()
+
+	leafDER := generateTestLeaf(t, issuerKey, issuerCert, responder.URL)
+
+	bundle := append(pemEncodeCert(leafDER), pemEncodeCert(issuerDER)...)
+
+	rawResp, parsedResp, err := GetOCSPForCert(bundle)
+	if err != nil {
+		t.Fatalf("GetOCSPForCert returned an error: %v", err)
+	}
+
+	if len(rawResp) == 0 {
+		t.Fatal("expected a non-empty raw OCSP response")
+	}
+
+	if parsedResp.Status != ocsp.Good {
+		t.Fatalf("expected OCSP status Good, got %v", parsedResp.Status)
+	}
+}
+
+func TestGenerateCsrWithOptionsSynthesizesCNFromSAN(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	der, err := GenerateCsrWithOptions(key, "", []string{"a.example.com", "b.example.com"}, false, true)
+	if err != nil {
+		t.Fatalf("GenerateCsrWithOptions returned an error: %v", err)
+	}
+
+	csr, err := x509.ParseCertificateRequest(der)
+	if err != nil {
+		t.Fatalf("failed to parse CSR: %v", err)
+	}
+
+	if csr.Subject.CommonName != "a.example.com" {
+		t.Fatalf("expected CommonName to be synthesized from the first SAN, got %q", csr.Subject.CommonName)
+	}
+}
+
+func TestGenerateCsrWithOptionsLeavesCNEmptyWithoutSynthesize(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	der, err := GenerateCsrWithOptions(key, "", []string{"a.example.com"}, false, false)
+	if err != nil {
+		t.Fatalf("GenerateCsrWithOptions returned an error: %v", err)
+	}
+
+	csr, err := x509.ParseCertificateRequest(der)
+	if err != nil {
+		t.Fatalf("failed to parse CSR: %v", err)
+	}
+
+	if csr.Subject.CommonName != "" {
+		t.Fatalf("expected CommonName to stay empty, got %q", csr.Subject.CommonName)
+	}
+}
+
+func TestGenerateCsrMatchesPreExistingFourArgBehavior(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	der, err := GenerateCsr(key, "", []string{"a.example.com"}, false)
+	if err != nil {
+		t.Fatalf("GenerateCsr returned an error: %v", err)
+	}
+
+	csr, err := x509.ParseCertificateRequest(der)
+	if err != nil {
+		t.Fatalf("failed to parse CSR: %v", err)
+	}
+
+	if csr.Subject.CommonName != "" {
+		t.Fatalf("expected GenerateCsr to leave CommonName empty like before synthesizeCNFromSAN existed, got %q", csr.Subject.CommonName)
+	}
+
+	if len(csr.DNSNames) != 1 || csr.DNSNames[0] != "a.example.com" {
+		t.Fatalf("expected the SAN to be carried through, got %v", csr.DNSNames)
+	}
+}
+
+func generateTestCA(t *testing.T) (*rsa.PrivateKey, *x509.Certificate, []byte) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate CA key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create CA certificate: %v", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse CA certificate: %v", err)
+	}
+
+	return key, cert, der
+}
+
+func generateTestLeaf(t *testing.T, issuerKey *rsa.PrivateKey, issuerCert *x509.Certificate, ocspServer string) []byte {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate leaf key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		OCSPServer:   []string{ocspServer},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, issuerCert, &key.PublicKey, issuerKey)
+	if err != nil {
+		t.Fatalf("failed to create leaf certificate: %v", err)
+	}
+
+	return der
+}
+
+func pemEncodeCert(der []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
@@ -0,0 +1,70 @@
+package registration
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+)
+
+// jwk is the minimal JSON Web Key encoding needed to identify an account key
+// in an ACME JWS, per RFC 7518 section 6.
+type jwk struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+}
+
+// publicKeyFor extracts the public key from a private key of a type
+// supported by certcrypto.GeneratePrivateKey.
+func publicKeyFor(privateKey crypto.PrivateKey) crypto.PublicKey {
+	switch key := privateKey.(type) {
+	case *ecdsa.PrivateKey:
+		return &key.PublicKey
+	case *rsa.PrivateKey:
+		return &key.PublicKey
+	default:
+		return nil
+	}
+}
+
+func jwkFromPublicKey(pub crypto.PublicKey) (*jwk, error) {
+	switch key := pub.(type) {
+	case *ecdsa.PublicKey:
+		size := curveByteSize(key.Curve.Params().BitSize)
+		return &jwk{
+			Kty: "EC",
+			Crv: key.Curve.Params().Name,
+			X:   base64.RawURLEncoding.EncodeToString(padBigInt(key.X, size)),
+			Y:   base64.RawURLEncoding.EncodeToString(padBigInt(key.Y, size)),
+		}, nil
+	case *rsa.PublicKey:
+		return &jwk{
+			Kty: "RSA",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}, nil
+	default:
+		return nil, fmt.Errorf("registration: unsupported public key type %T", pub)
+	}
+}
+
+func curveByteSize(bitSize int) int {
+	return (bitSize + 7) / 8
+}
+
+func padBigInt(i *big.Int, size int) []byte {
+	b := i.Bytes()
+	if len(b) >= size {
+		return b
+	}
+
+	padded := make([]byte, size)
+	copy(padded[size-len(b):], b)
+	return padded
+}
@@ -0,0 +1,138 @@
+package registration
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// flattenedJWS is the flattened JSON serialization of a JWS (RFC 7515
+// section 7.2.2), which is what the ACME protocol uses on the wire.
+type flattenedJWS struct {
+	Protected string `json:"protected"`
+	Payload   string `json:"payload"`
+	Signature string `json:"signature"`
+}
+
+func encodeSegment(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// signJWS signs payload with privateKey, embedding header (which must at
+// least carry either "jwk" or "kid") as the protected header. The "alg"
+// entry is set from the type of privateKey, overwriting any value in header.
+func signJWS(privateKey crypto.PrivateKey, header map[string]interface{}, payload []byte) (*flattenedJWS, error) {
+	alg, hashed, err := digestFor(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	header["alg"] = alg
+
+	protected, err := json.Marshal(header)
+	if err != nil {
+		return nil, fmt.Errorf("registration: failed to encode JWS header: %w", err)
+	}
+
+	protectedEncoded := encodeSegment(protected)
+	payloadEncoded := encodeSegment(payload)
+	signingInput := protectedEncoded + "." + payloadEncoded
+
+	sig, err := sign(privateKey, hashed([]byte(signingInput)))
+	if err != nil {
+		return nil, err
+	}
+
+	return &flattenedJWS{
+		Protected: protectedEncoded,
+		Payload:   payloadEncoded,
+		Signature: encodeSegment(sig),
+	}, nil
+}
+
+// signHMACJWS signs payload with an HMAC-SHA256 key (used for the
+// externalAccountBinding JWS, which is always HS256 per RFC 8555 section 7.3.4).
+func signHMACJWS(hmacKey []byte, header map[string]interface{}, payload []byte) (*flattenedJWS, error) {
+	header["alg"] = "HS256"
+
+	protected, err := json.Marshal(header)
+	if err != nil {
+		return nil, fmt.Errorf("registration: failed to encode EAB JWS header: %w", err)
+	}
+
+	protectedEncoded := encodeSegment(protected)
+	payloadEncoded := encodeSegment(payload)
+	signingInput := protectedEncoded + "." + payloadEncoded
+
+	mac := hmac.New(sha256.New, hmacKey)
+	mac.Write([]byte(signingInput))
+
+	return &flattenedJWS{
+		Protected: protectedEncoded,
+		Payload:   payloadEncoded,
+		Signature: encodeSegment(mac.Sum(nil)),
+	}, nil
+}
+
+// digestFor returns the JWS "alg" name for privateKey and a function that
+// hashes a signing input the way that algorithm expects it.
+func digestFor(privateKey crypto.PrivateKey) (string, func([]byte) []byte, error) {
+	switch key := privateKey.(type) {
+	case *ecdsa.PrivateKey:
+		switch key.Curve.Params().Name {
+		case "P-256":
+			return "ES256", sum256, nil
+		case "P-384":
+			return "ES384", sum384, nil
+		default:
+			return "", nil, fmt.Errorf("registration: unsupported ECDSA curve %s", key.Curve.Params().Name)
+		}
+	case *rsa.PrivateKey:
+		return "RS256", sum256, nil
+	default:
+		return "", nil, fmt.Errorf("registration: unsupported private key type %T", privateKey)
+	}
+}
+
+func sum256(data []byte) []byte {
+	sum := sha256.Sum256(data)
+	return sum[:]
+}
+
+func sum384(data []byte) []byte {
+	sum := sha512.Sum384(data)
+	return sum[:]
+}
+
+func sign(privateKey crypto.PrivateKey, hashed []byte) ([]byte, error) {
+	switch key := privateKey.(type) {
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, key, hashed)
+		if err != nil {
+			return nil, fmt.Errorf("registration: failed to sign JWS: %w", err)
+		}
+
+		size := curveByteSize(key.Curve.Params().BitSize)
+		sig := make([]byte, 2*size)
+		copy(sig[size-len(r.Bytes()):size], r.Bytes())
+		copy(sig[2*size-len(s.Bytes()):], s.Bytes())
+
+		return sig, nil
+	case *rsa.PrivateKey:
+		sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hashed)
+		if err != nil {
+			return nil, fmt.Errorf("registration: failed to sign JWS: %w", err)
+		}
+
+		return sig, nil
+	default:
+		return nil, fmt.Errorf("registration: unsupported private key type %T", privateKey)
+	}
+}
@@ -0,0 +1,120 @@
+package registration
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"testing"
+)
+
+func TestSignJWSRSA(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+
+	header := map[string]interface{}{"nonce": "test-nonce", "url": "https://example.com/new-acct"}
+	jws, err := signJWS(privateKey, header, []byte(`{"termsOfServiceAgreed":true}`))
+	if err != nil {
+		t.Fatalf("signJWS returned an error: %v", err)
+	}
+
+	var protected map[string]interface{}
+	decodeSegment(t, jws.Protected, &protected)
+
+	if protected["alg"] != "RS256" {
+		t.Fatalf("expected alg RS256, got %v", protected["alg"])
+	}
+
+	signingInput := jws.Protected + "." + jws.Payload
+	sig, err := base64.RawURLEncoding.DecodeString(jws.Signature)
+	if err != nil {
+		t.Fatalf("failed to decode signature: %v", err)
+	}
+
+	hashed := sum256([]byte(signingInput))
+	if err := rsa.VerifyPKCS1v15(&privateKey.PublicKey, crypto.SHA256, hashed, sig); err != nil {
+		t.Fatalf("RS256 signature does not verify: %v", err)
+	}
+}
+
+func TestSignJWSES384(t *testing.T) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate P-384 key: %v", err)
+	}
+
+	header := map[string]interface{}{"nonce": "test-nonce", "url": "https://example.com/new-acct"}
+	jws, err := signJWS(privateKey, header, []byte(`{"termsOfServiceAgreed":true}`))
+	if err != nil {
+		t.Fatalf("signJWS returned an error: %v", err)
+	}
+
+	var protected map[string]interface{}
+	decodeSegment(t, jws.Protected, &protected)
+
+	if protected["alg"] != "ES384" {
+		t.Fatalf("expected alg ES384, got %v", protected["alg"])
+	}
+
+	signingInput := jws.Protected + "." + jws.Payload
+	sig, err := base64.RawURLEncoding.DecodeString(jws.Signature)
+	if err != nil {
+		t.Fatalf("failed to decode signature: %v", err)
+	}
+
+	size := curveByteSize(privateKey.Curve.Params().BitSize)
+	if len(sig) != 2*size {
+		t.Fatalf("expected a %d-byte raw r||s signature, got %d bytes", 2*size, len(sig))
+	}
+
+	r := new(big.Int).SetBytes(sig[:size])
+	s := new(big.Int).SetBytes(sig[size:])
+
+	hashed := sum384([]byte(signingInput))
+	if !ecdsa.Verify(&privateKey.PublicKey, hashed, r, s) {
+		t.Fatal("ES384 signature does not verify")
+	}
+}
+
+func TestBuildEABJWSRejectsMalformedHMACKey(t *testing.T) {
+	accountKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate account key: %v", err)
+	}
+
+	accountJWK, err := jwkFromPublicKey(&accountKey.PublicKey)
+	if err != nil {
+		t.Fatalf("failed to build account JWK: %v", err)
+	}
+
+	eab := &ExternalAccountBinding{KID: "kid-123", HMACKey: "not valid base64url!!"}
+
+	if _, err := buildEABJWS(eab, accountJWK, "https://example.com/new-acct"); err == nil {
+		t.Fatal("expected buildEABJWS to reject a malformed HMAC key")
+	}
+}
+
+func TestDigestForRejectsUnsupportedKeyType(t *testing.T) {
+	if _, _, err := digestFor("not a key"); err == nil {
+		t.Fatal("expected digestFor to reject an unsupported private key type")
+	}
+}
+
+func decodeSegment(t *testing.T, segment string, v interface{}) {
+	t.Helper()
+
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		t.Fatalf("failed to decode segment: %v", err)
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatalf("failed to unmarshal segment: %v", err)
+	}
+}
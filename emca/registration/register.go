@@ -0,0 +1,174 @@
+package registration
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Account is the ACME server's view of a registered account, as returned by
+// the newAccount endpoint.
+type Account struct {
+	URI     string   `json:"-"`
+	Status  string   `json:"status"`
+	Contact []string `json:"contact,omitempty"`
+}
+
+type directory struct {
+	NewNonce   string `json:"newNonce"`
+	NewAccount string `json:"newAccount"`
+}
+
+type newAccountRequest struct {
+	TermsOfServiceAgreed   bool          `json:"termsOfServiceAgreed"`
+	Contact                []string      `json:"contact,omitempty"`
+	ExternalAccountBinding *flattenedJWS `json:"externalAccountBinding,omitempty"`
+}
+
+// Register creates a new ACME account at directoryURL using accountKey to
+// sign the request. If eab is non-nil, it is embedded in the newAccount
+// request as a JWS-wrapped externalAccountBinding (RFC 8555 section 7.3.4),
+// which is what CAs such as ZeroSSL require in place of anonymous
+// registration.
+func Register(client *http.Client, directoryURL string, accountKey crypto.PrivateKey, contacts []string, eab *ExternalAccountBinding) (*Account, error) {
+	dir, err := fetchDirectory(client, directoryURL)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := fetchNonce(client, dir.NewNonce)
+	if err != nil {
+		return nil, err
+	}
+
+	accountJWK, err := jwkFromPublicKey(publicKeyFor(accountKey))
+	if err != nil {
+		return nil, err
+	}
+
+	body := newAccountRequest{
+		TermsOfServiceAgreed: true,
+		Contact:              contacts,
+	}
+
+	if eab != nil {
+		eabJWS, err := buildEABJWS(eab, accountJWK, dir.NewAccount)
+		if err != nil {
+			return nil, err
+		}
+
+		body.ExternalAccountBinding = eabJWS
+	}
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("registration: failed to encode newAccount request: %w", err)
+	}
+
+	header := map[string]interface{}{
+		"jwk":   accountJWK,
+		"nonce": nonce,
+		"url":   dir.NewAccount,
+	}
+
+	jws, err := signJWS(accountKey, header, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return postAccount(client, dir.NewAccount, jws)
+}
+
+// buildEABJWS builds the inner JWS that binds accountJWK to the CA account
+// identified by eab.KID, signed with the CA-issued HMAC key.
+func buildEABJWS(eab *ExternalAccountBinding, accountJWK *jwk, newAccountURL string) (*flattenedJWS, error) {
+	hmacKey, err := base64.RawURLEncoding.DecodeString(eab.HMACKey)
+	if err != nil {
+		return nil, fmt.Errorf("registration: invalid EAB HMAC key: %w", err)
+	}
+
+	payload, err := json.Marshal(accountJWK)
+	if err != nil {
+		return nil, fmt.Errorf("registration: failed to encode account JWK: %w", err)
+	}
+
+	header := map[string]interface{}{
+		"kid": eab.KID,
+		"url": newAccountURL,
+	}
+
+	return signHMACJWS(hmacKey, header, payload)
+}
+
+func fetchDirectory(client *http.Client, directoryURL string) (*directory, error) {
+	resp, err := client.Get(directoryURL)
+	if err != nil {
+		return nil, fmt.Errorf("registration: failed to fetch directory: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("registration: directory request returned status code %d", resp.StatusCode)
+	}
+
+	var dir directory
+	if err := json.NewDecoder(resp.Body).Decode(&dir); err != nil {
+		return nil, fmt.Errorf("registration: failed to decode directory: %w", err)
+	}
+
+	return &dir, nil
+}
+
+func fetchNonce(client *http.Client, newNonceURL string) (string, error) {
+	resp, err := client.Head(newNonceURL)
+	if err != nil {
+		return "", fmt.Errorf("registration: failed to fetch nonce: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return "", fmt.Errorf("registration: newNonce request returned status code %d", resp.StatusCode)
+	}
+
+	nonce := resp.Header.Get("Replay-Nonce")
+	if nonce == "" {
+		return "", fmt.Errorf("registration: server did not return a Replay-Nonce")
+	}
+
+	return nonce, nil
+}
+
+func postAccount(client *http.Client, newAccountURL string, jws *flattenedJWS) (*Account, error) {
+	payload, err := json.Marshal(jws)
+	if err != nil {
+		return nil, fmt.Errorf("registration: failed to encode JWS: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, newAccountURL, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("registration: failed to build newAccount request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/jose+json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("registration: newAccount request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("registration: newAccount request returned status code %d", resp.StatusCode)
+	}
+
+	var account Account
+	if err := json.NewDecoder(resp.Body).Decode(&account); err != nil {
+		return nil, fmt.Errorf("registration: failed to decode account: %w", err)
+	}
+
+	account.URI = resp.Header.Get("Location")
+
+	return &account, nil
+}
@@ -0,0 +1,163 @@
+package registration
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRegisterWithExternalAccountBinding(t *testing.T) {
+	accountKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate account key: %v", err)
+	}
+
+	eab := &ExternalAccountBinding{
+		KID:     "kid-123",
+		HMACKey: base64.RawURLEncoding.EncodeToString([]byte("super-secret-hmac-key")),
+	}
+
+	var sawEAB bool
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/directory", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(directory{
+			NewNonce:   "http://" + r.Host + "/new-nonce",
+			NewAccount: "http://" + r.Host + "/new-acct",
+		})
+	})
+	mux.HandleFunc("/new-nonce", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Replay-Nonce", "test-nonce")
+	})
+	mux.HandleFunc("/new-acct", func(w http.ResponseWriter, r *http.Request) {
+		var outer flattenedJWS
+		if err := json.NewDecoder(r.Body).Decode(&outer); err != nil {
+			t.Fatalf("failed to decode outer JWS: %v", err)
+		}
+
+		payload, err := base64.RawURLEncoding.DecodeString(outer.Payload)
+		if err != nil {
+			t.Fatalf("failed to decode outer payload: %v", err)
+		}
+
+		var body newAccountRequest
+		if err := json.Unmarshal(payload, &body); err != nil {
+			t.Fatalf("failed to unmarshal newAccount request: %v", err)
+		}
+
+		if body.ExternalAccountBinding == nil {
+			t.Fatal("expected externalAccountBinding to be set")
+		}
+
+		hmacKey, _ := base64.RawURLEncoding.DecodeString(eab.HMACKey)
+		signingInput := body.ExternalAccountBinding.Protected + "." + body.ExternalAccountBinding.Payload
+		mac := hmac.New(sha256.New, hmacKey)
+		mac.Write([]byte(signingInput))
+		expected := base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+
+		if body.ExternalAccountBinding.Signature != expected {
+			t.Fatal("EAB JWS signature does not verify against the HMAC key")
+		}
+
+		sawEAB = true
+
+		w.Header().Set("Location", "http://"+r.Host+"/acct/1")
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(Account{Status: "valid"})
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	account, err := Register(server.Client(), server.URL+"/directory", accountKey, []string{"mailto:user@example.com"}, eab)
+	if err != nil {
+		t.Fatalf("Register returned an error: %v", err)
+	}
+
+	if !sawEAB {
+		t.Fatal("newAccount handler was never reached with an externalAccountBinding")
+	}
+
+	if account.Status != "valid" || account.URI == "" {
+		t.Fatalf("unexpected account: %+v", account)
+	}
+}
+
+func TestRegisterFailsOnDirectoryErrorStatus(t *testing.T) {
+	accountKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate account key: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/directory", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "service unavailable", http.StatusServiceUnavailable)
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	if _, err := Register(server.Client(), server.URL+"/directory", accountKey, nil, nil); err == nil {
+		t.Fatal("expected Register to fail when the directory request returns a non-2xx status")
+	}
+}
+
+func TestRegisterFailsOnNonceErrorStatus(t *testing.T) {
+	accountKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate account key: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/directory", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(directory{
+			NewNonce:   "http://" + r.Host + "/new-nonce",
+			NewAccount: "http://" + r.Host + "/new-acct",
+		})
+	})
+	mux.HandleFunc("/new-nonce", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	if _, err := Register(server.Client(), server.URL+"/directory", accountKey, nil, nil); err == nil {
+		t.Fatal("expected Register to fail when the newNonce request returns a non-2xx status")
+	}
+}
+
+func TestRegisterFailsOnNewAccountErrorStatus(t *testing.T) {
+	accountKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate account key: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/directory", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(directory{
+			NewNonce:   "http://" + r.Host + "/new-nonce",
+			NewAccount: "http://" + r.Host + "/new-acct",
+		})
+	})
+	mux.HandleFunc("/new-nonce", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Replay-Nonce", "test-nonce")
+	})
+	mux.HandleFunc("/new-acct", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "conflict", http.StatusConflict)
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	if _, err := Register(server.Client(), server.URL+"/directory", accountKey, nil, nil); err == nil {
+		t.Fatal("expected Register to fail when the newAccount request returns a non-2xx status")
+	}
+}
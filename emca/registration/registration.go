@@ -0,0 +1,12 @@
+// Package registration deals with ACME account registration, including
+// support for External Account Binding (EAB) as required by CAs such as
+// ZeroSSL that don't allow anonymous account creation.
+package registration
+
+// ExternalAccountBinding carries the key identifier and MAC key a CA issues
+// out-of-band (e.g. through a web dashboard or, for ZeroSSL, an API call)
+// that must be used to bind a new ACME account to an existing CA account.
+type ExternalAccountBinding struct {
+	KID     string
+	HMACKey string
+}
@@ -0,0 +1,56 @@
+package registration
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// zeroSSLEABEndpoint is ZeroSSL's endpoint for exchanging an account email
+// for a one-time set of EAB credentials, so users don't have to copy them
+// out of the ZeroSSL dashboard by hand.
+const zeroSSLEABEndpoint = "https://api.zerossl.com/acme/eab-credentials-email"
+
+var zeroSSLClient = &http.Client{Timeout: 30 * time.Second}
+
+type zeroSSLEABResponse struct {
+	Success bool   `json:"success"`
+	EABKID  string `json:"eab_kid"`
+	EABHMAC string `json:"eab_hmac_key"`
+	Error   struct {
+		Code int    `json:"code"`
+		Type string `json:"type"`
+	} `json:"error"`
+}
+
+// FetchZeroSSLEAB exchanges email for a freshly minted ExternalAccountBinding,
+// so a caller registering against ZeroSSL's ACME endpoint doesn't need to
+// pre-provision EAB credentials through the ZeroSSL dashboard.
+func FetchZeroSSLEAB(email string) (*ExternalAccountBinding, error) {
+	return fetchZeroSSLEAB(zeroSSLClient, zeroSSLEABEndpoint, email)
+}
+
+func fetchZeroSSLEAB(client *http.Client, endpoint, email string) (*ExternalAccountBinding, error) {
+	resp, err := client.PostForm(endpoint, url.Values{"email": {email}})
+	if err != nil {
+		return nil, fmt.Errorf("registration: failed to request ZeroSSL EAB credentials: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("registration: ZeroSSL EAB request returned status code %d", resp.StatusCode)
+	}
+
+	var body zeroSSLEABResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("registration: failed to decode ZeroSSL EAB response: %w", err)
+	}
+
+	if !body.Success {
+		return nil, fmt.Errorf("registration: ZeroSSL EAB request failed: %s (code %d)", body.Error.Type, body.Error.Code)
+	}
+
+	return &ExternalAccountBinding{KID: body.EABKID, HMACKey: body.EABHMAC}, nil
+}
@@ -0,0 +1,35 @@
+package registration
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFetchZeroSSLEAB(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"success":true,"eab_kid":"kid-123","eab_hmac_key":"hmac-456"}`))
+	}))
+	defer server.Close()
+
+	eab, err := fetchZeroSSLEAB(server.Client(), server.URL, "user@example.com")
+	if err != nil {
+		t.Fatalf("fetchZeroSSLEAB returned an error: %v", err)
+	}
+
+	if eab.KID != "kid-123" || eab.HMACKey != "hmac-456" {
+		t.Fatalf("unexpected EAB credentials: %+v", eab)
+	}
+}
+
+func TestFetchZeroSSLEABFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"success":false,"error":{"code":2520,"type":"invalid_email"}}`))
+	}))
+	defer server.Close()
+
+	_, err := fetchZeroSSLEAB(server.Client(), server.URL, "not-an-email")
+	if err == nil {
+		t.Fatal("expected an error for a failed EAB request")
+	}
+}
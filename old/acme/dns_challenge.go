@@ -0,0 +1,146 @@
+package acme
+
+import (
+	"fmt"
+	"time"
+)
+
+// Authorization is the minimal piece of state the orchestrator needs per
+// domain to drive a dns-01 challenge: the domain being authorized, the
+// challenge token, and the computed key authorization.
+type Authorization struct {
+	Domain  string
+	Token   string
+	KeyAuth string
+}
+
+// ChallengeProvider is the subset of a DNS provider's API the orchestrator
+// needs to drive a dns-01 challenge for a single domain.
+type ChallengeProvider interface {
+	Present(domain, token, keyAuth string) error
+	CleanUp(domain, token, keyAuth string) error
+	Timeout() (timeout, interval time.Duration)
+}
+
+// SolveAll drives the dns-01 challenge for every authorization in auths
+// using provider, returning an error for each domain whose challenge could
+// not be presented, validated or cleaned up.
+//
+// If provider also implements PreSolver, every PreSolve call is issued
+// up front, the orchestrator waits once for propagation across the whole
+// batch, every challenge is validated, and CleanUpAll removes everything
+// in one call. This turns what would otherwise be a serialized
+// create/wait/verify per domain into three batched passes, which matters
+// most for SAN certificates with many names on providers where zone
+// lookups and record creation dominate wall time.
+//
+// Providers that don't implement PreSolver fall back to the per-domain
+// Present/wait/verify/CleanUp path.
+func SolveAll(provider ChallengeProvider, auths []Authorization, validate func(Authorization) error) map[string]error {
+	if presolver, ok := provider.(PreSolver); ok {
+		return solveBatched(provider, presolver, auths, validate)
+	}
+
+	return solveSequential(provider, auths, validate)
+}
+
+func solveSequential(provider ChallengeProvider, auths []Authorization, validate func(Authorization) error) map[string]error {
+	errs := make(map[string]error)
+	timeout, interval := provider.Timeout()
+
+	for _, auth := range auths {
+		if err := provider.Present(auth.Domain, auth.Token, auth.KeyAuth); err != nil {
+			errs[auth.Domain] = fmt.Errorf("could not present challenge: %w", err)
+			continue
+		}
+
+		err := waitFor(timeout, interval, func() (bool, error) {
+			validateErr := validate(auth)
+			return validateErr == nil, validateErr
+		})
+		if err != nil {
+			errs[auth.Domain] = err
+		}
+
+		if cleanupErr := provider.CleanUp(auth.Domain, auth.Token, auth.KeyAuth); cleanupErr != nil && errs[auth.Domain] == nil {
+			errs[auth.Domain] = fmt.Errorf("could not clean up challenge: %w", cleanupErr)
+		}
+	}
+
+	return errs
+}
+
+func solveBatched(provider ChallengeProvider, presolver PreSolver, auths []Authorization, validate func(Authorization) error) map[string]error {
+	errs := make(map[string]error)
+	pending := make([]Authorization, 0, len(auths))
+	domains := make([]string, 0, len(auths))
+
+	for _, auth := range auths {
+		if err := presolver.PreSolve(auth.Domain, auth.Token, auth.KeyAuth); err != nil {
+			errs[auth.Domain] = fmt.Errorf("could not pre-solve challenge: %w", err)
+			continue
+		}
+
+		pending = append(pending, auth)
+		domains = append(domains, auth.Domain)
+	}
+
+	timeout, interval := provider.Timeout()
+
+	// A single shared wait covers every domain in the batch, instead of
+	// waiting out a fresh propagation timeout per domain.
+	remaining := make(map[string]Authorization, len(pending))
+	lastErr := make(map[string]error, len(pending))
+	for _, auth := range pending {
+		remaining[auth.Domain] = auth
+	}
+
+	_ = waitFor(timeout, interval, func() (bool, error) {
+		for domain, auth := range remaining {
+			if err := validate(auth); err == nil {
+				delete(remaining, domain)
+			} else {
+				lastErr[domain] = err
+			}
+		}
+
+		return len(remaining) == 0, nil
+	})
+
+	for domain := range remaining {
+		errs[domain] = fmt.Errorf("could not validate challenge before the propagation timeout: %w", lastErr[domain])
+	}
+
+	if err := presolver.CleanUpAll(domains); err != nil {
+		for _, domain := range domains {
+			if errs[domain] == nil {
+				errs[domain] = fmt.Errorf("could not clean up challenge: %w", err)
+			}
+		}
+	}
+
+	return errs
+}
+
+// waitFor polls check every interval until it reports done, or until
+// timeout elapses.
+func waitFor(timeout, interval time.Duration, check func() (bool, error)) error {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		done, err := check()
+		if done {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			if err != nil {
+				return err
+			}
+
+			return fmt.Errorf("timed out waiting for propagation")
+		}
+
+		time.Sleep(interval)
+	}
+}
@@ -0,0 +1,114 @@
+package acme
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type fakeProvider struct {
+	presolveCalls  []string
+	cleanUpAllArgs []string
+}
+
+func (f *fakeProvider) Present(domain, token, keyAuth string) error { return nil }
+func (f *fakeProvider) CleanUp(domain, token, keyAuth string) error { return nil }
+
+func (f *fakeProvider) Timeout() (time.Duration, time.Duration) {
+	return 200 * time.Millisecond, 10 * time.Millisecond
+}
+
+func (f *fakeProvider) PreSolve(domain, token, keyAuth string) error {
+	f.presolveCalls = append(f.presolveCalls, domain)
+	return nil
+}
+
+func (f *fakeProvider) CleanUpAll(domains []string) error {
+	f.cleanUpAllArgs = domains
+	return nil
+}
+
+func TestSolveAllBatchesPreSolver(t *testing.T) {
+	provider := &fakeProvider{}
+
+	auths := []Authorization{
+		{Domain: "a.example.com", Token: "tok-a", KeyAuth: "key-a"},
+		{Domain: "b.example.com", Token: "tok-b", KeyAuth: "key-b"},
+	}
+
+	validated := make(map[string]bool)
+
+	errs := SolveAll(provider, auths, func(auth Authorization) error {
+		validated[auth.Domain] = true
+		return nil
+	})
+
+	if len(errs) != 0 {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+
+	if len(provider.presolveCalls) != 2 {
+		t.Fatalf("expected PreSolve to be called once per domain up front, got %v", provider.presolveCalls)
+	}
+
+	if len(provider.cleanUpAllArgs) != 2 {
+		t.Fatalf("expected CleanUpAll to be called once with every domain, got %v", provider.cleanUpAllArgs)
+	}
+
+	for _, auth := range auths {
+		if !validated[auth.Domain] {
+			t.Fatalf("expected %s to be validated", auth.Domain)
+		}
+	}
+}
+
+type sequentialProvider struct {
+	presentCalls []string
+	cleanUpCalls []string
+}
+
+func (f *sequentialProvider) Present(domain, token, keyAuth string) error {
+	f.presentCalls = append(f.presentCalls, domain)
+	return nil
+}
+
+func (f *sequentialProvider) CleanUp(domain, token, keyAuth string) error {
+	f.cleanUpCalls = append(f.cleanUpCalls, domain)
+	return nil
+}
+
+func (f *sequentialProvider) Timeout() (time.Duration, time.Duration) {
+	return 200 * time.Millisecond, 10 * time.Millisecond
+}
+
+func TestSolveAllFallsBackWithoutPreSolver(t *testing.T) {
+	provider := &sequentialProvider{}
+
+	auths := []Authorization{
+		{Domain: "a.example.com"},
+		{Domain: "b.example.com"},
+	}
+
+	errs := SolveAll(provider, auths, func(auth Authorization) error { return nil })
+	if len(errs) != 0 {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+
+	if len(provider.presentCalls) != 2 || len(provider.cleanUpCalls) != 2 {
+		t.Fatalf("expected Present/CleanUp to run per domain, got present=%v cleanUp=%v", provider.presentCalls, provider.cleanUpCalls)
+	}
+}
+
+func TestSolveAllReportsValidationFailure(t *testing.T) {
+	provider := &fakeProvider{}
+
+	auths := []Authorization{{Domain: "broken.example.com"}}
+
+	errs := SolveAll(provider, auths, func(auth Authorization) error {
+		return errors.New("dns not propagated")
+	})
+
+	if err := errs["broken.example.com"]; err == nil {
+		t.Fatal("expected a validation error to be reported")
+	}
+}
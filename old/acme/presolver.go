@@ -0,0 +1,19 @@
+package acme
+
+// PreSolver is an optional interface that a DNS provider can implement to allow
+// the challenge orchestrator to batch the work it does across every authorization
+// in a certificate request, instead of serializing create/wait/verify per domain.
+//
+// When a provider implements PreSolver, the orchestrator calls PreSolve once per
+// domain to create all the required records up front, waits once for propagation
+// across the whole batch, verifies every challenge, and finally calls CleanUpAll
+// once to remove everything it created. Providers that don't implement PreSolver
+// fall back to the existing Present/CleanUp path, one domain at a time.
+type PreSolver interface {
+	// PreSolve creates the record needed to fulfill the dns-01 challenge for domain,
+	// without waiting for propagation.
+	PreSolve(domain, token, keyAuth string) error
+
+	// CleanUpAll removes every record created by PreSolve for the given domains.
+	CleanUpAll(domains []string) error
+}
@@ -2,13 +2,16 @@
 package acmeproxy
 
 import (
-	"encoding/json"
 	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"net/http"
-	"time"
 	"strings"
+	"time"
 
 	"github.com/xenolf/lego/platform/config/env"
 )
@@ -17,19 +20,28 @@ import (
 type Config struct {
 	BaseURL            string
 	Provider           string
+	Username           string
+	Password           string
+	Token              string
+	UserAgent          string
+	HTTPClient         *http.Client
+	HTTPTimeout        time.Duration
 	PropagationTimeout time.Duration
 	PollingInterval    time.Duration
 }
 
+// Request is the payload sent to acme-proxy for both Present and CleanUp.
 type Request struct {
-	Domain   string `json:"domain"`
-	Token    string `json:"token"`
-	KeyAuth  string `json:"keyAuth'`
+	Domain  string `json:"domain"`
+	Token   string `json:"token"`
+	KeyAuth string `json:"keyAuth"`
 }
 
 // NewDefaultConfig returns a default configuration for the DNSProvider
 func NewDefaultConfig() *Config {
 	return &Config{
+		UserAgent:          "lego-acmeproxy",
+		HTTPTimeout:        env.GetOrDefaultSecond("ACMEPROXY_HTTP_TIMEOUT", 30*time.Second),
 		PropagationTimeout: env.GetOrDefaultSecond("ACMEPROXY_PROPAGATION_TIMEOUT", 10*time.Minute),
 		PollingInterval:    env.GetOrDefaultSecond("ACMEPROXY_POLLING_INTERVAL", 10*time.Second),
 	}
@@ -41,17 +53,26 @@ type DNSProvider struct {
 }
 
 // NewDNSProvider returns a DNSProvider instance configured for acme-proxy.
+// Credentials must be passed in the environment variable ACMEPROXY_BASEURL,
+// optionally combined with ACMEPROXY_USERNAME/ACMEPROXY_PASSWORD or ACMEPROXY_TOKEN.
 func NewDNSProvider() (*DNSProvider, error) {
 	values, err := env.Get("ACMEPROXY_BASEURL")
 	if err != nil {
-		return nil, fmt.Errorf("acmeproxy: %v", err)
+		return nil, fmt.Errorf("acmeproxy: %w", err)
 	}
 
 	config := NewDefaultConfig()
 	config.BaseURL = strings.TrimSuffix(values["ACMEPROXY_BASEURL"], "/")
+	config.Username = env.GetOrFile("ACMEPROXY_USERNAME")
+	config.Password = env.GetOrFile("ACMEPROXY_PASSWORD")
+	config.Token = env.GetOrFile("ACMEPROXY_TOKEN")
+
+	client, err := newHTTPClient(config.HTTPTimeout)
 	if err != nil {
-		return nil, fmt.Errorf("wrong port", err)
+		return nil, fmt.Errorf("acmeproxy: %w", err)
 	}
+	config.HTTPClient = client
+
 	return NewDNSProviderConfig(config)
 }
 
@@ -61,6 +82,14 @@ func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
 		return nil, errors.New("acmeproxy: the configuration of the DNS provider is nil")
 	}
 
+	if config.BaseURL == "" {
+		return nil, errors.New("acmeproxy: the base URL is missing")
+	}
+
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: config.HTTPTimeout}
+	}
+
 	return &DNSProvider{config: config}, nil
 }
 
@@ -72,17 +101,8 @@ func (d *DNSProvider) Timeout() (timeout, interval time.Duration) {
 
 // Present creates a TXT record to fulfill the dns-01 challenge
 func (d *DNSProvider) Present(domain, token, keyAuth string) error {
-
-	req := Request{
-		Domain:  domain,
-		Token:   token,
-		KeyAuth: keyAuth,
-	}
-	b := new (bytes.Buffer)
-	json.NewEncoder(b).Encode(req)
-	_, err := http.Post(d.config.BaseURL+"/present", "application/json", b)
-	if err != nil {
-		return fmt.Errorf("acmeproxy: error for %s in Present: %v", domain, err)
+	if err := d.doRequest("present", domain, token, keyAuth); err != nil {
+		return fmt.Errorf("acmeproxy: error for %s in Present: %w", domain, err)
 	}
 
 	return nil
@@ -90,18 +110,77 @@ func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 
 // CleanUp removes the TXT record matching the specified parameters
 func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
+	if err := d.doRequest("cleanup", domain, token, keyAuth); err != nil {
+		return fmt.Errorf("acmeproxy: error for %s in CleanUp: %w", domain, err)
+	}
 
+	return nil
+}
+
+func (d *DNSProvider) doRequest(action, domain, token, keyAuth string) error {
 	req := Request{
 		Domain:  domain,
 		Token:   token,
 		KeyAuth: keyAuth,
 	}
-	b := new (bytes.Buffer)
-	json.NewEncoder(b).Encode(req)
-	_, err := http.Post(d.config.BaseURL+"/cleanup", "application/json", b)
+
+	body := new(bytes.Buffer)
+	if err := json.NewEncoder(body).Encode(req); err != nil {
+		return fmt.Errorf("failed to encode request body: %w", err)
+	}
+
+	httpReq, err := http.NewRequest(http.MethodPost, d.config.BaseURL+"/"+action, body)
 	if err != nil {
-		return fmt.Errorf("acmeproxy: error for %s in Present: %v", domain, err)
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	if d.config.UserAgent != "" {
+		httpReq.Header.Set("User-Agent", d.config.UserAgent)
+	}
+
+	switch {
+	case d.config.Token != "":
+		httpReq.Header.Set("Authorization", "Bearer "+d.config.Token)
+	case d.config.Username != "" || d.config.Password != "":
+		httpReq.SetBasicAuth(d.config.Username, d.config.Password)
+	}
+
+	resp, err := d.config.HTTPClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("failed to perform request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(respBody))
 	}
 
 	return nil
 }
+
+func newHTTPClient(timeout time.Duration) (*http.Client, error) {
+	caFile := env.GetOrFile("ACMEPROXY_TLS_CA")
+	if caFile == "" {
+		return &http.Client{Timeout: timeout}, nil
+	}
+
+	caCert, err := ioutil.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ACMEPROXY_TLS_CA: %w", err)
+	}
+
+	caCertPool := x509.NewCertPool()
+	if !caCertPool.AppendCertsFromPEM(caCert) {
+		return nil, fmt.Errorf("failed to parse ACMEPROXY_TLS_CA")
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: caCertPool},
+		},
+	}, nil
+}
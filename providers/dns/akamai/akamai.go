@@ -0,0 +1,148 @@
+// Package akamai implements a DNS provider for solving the DNS-01 challenge using Akamai FastDNS.
+package akamai
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/configdns-v1"
+	"github.com/akamai/AkamaiOPEN-edgegrid-golang/edgegrid"
+	"github.com/xenolf/lego/old/acme"
+	"github.com/xenolf/lego/platform/config/env"
+)
+
+// Config is used to configure the creation of the DNSProvider
+type Config struct {
+	ClientToken        string
+	ClientSecret       string
+	AccessToken        string
+	Host               string
+	TTL                int
+	PropagationTimeout time.Duration
+	PollingInterval    time.Duration
+}
+
+// NewDefaultConfig returns a default configuration for the DNSProvider
+func NewDefaultConfig() *Config {
+	return &Config{
+		TTL:                env.GetOrDefaultInt("AKAMAI_TTL", 300),
+		PropagationTimeout: env.GetOrDefaultSecond("AKAMAI_PROPAGATION_TIMEOUT", acme.DefaultPropagationTimeout),
+		PollingInterval:    env.GetOrDefaultSecond("AKAMAI_POLLING_INTERVAL", acme.DefaultPollingInterval),
+	}
+}
+
+// DNSProvider describes a provider for Akamai FastDNS.
+type DNSProvider struct {
+	config *Config
+}
+
+// NewDNSProvider returns a DNSProvider instance configured for Akamai FastDNS.
+// Credentials must be passed in the environment variables:
+// AKAMAI_CLIENT_TOKEN, AKAMAI_CLIENT_SECRET, AKAMAI_ACCESS_TOKEN, and AKAMAI_HOST.
+func NewDNSProvider() (*DNSProvider, error) {
+	values, err := env.Get("AKAMAI_CLIENT_TOKEN", "AKAMAI_CLIENT_SECRET", "AKAMAI_ACCESS_TOKEN", "AKAMAI_HOST")
+	if err != nil {
+		return nil, fmt.Errorf("akamai: %w", err)
+	}
+
+	config := NewDefaultConfig()
+	config.ClientToken = values["AKAMAI_CLIENT_TOKEN"]
+	config.ClientSecret = values["AKAMAI_CLIENT_SECRET"]
+	config.AccessToken = values["AKAMAI_ACCESS_TOKEN"]
+	config.Host = values["AKAMAI_HOST"]
+
+	return NewDNSProviderConfig(config)
+}
+
+// NewDNSProviderConfig return a DNSProvider instance configured for Akamai FastDNS.
+func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
+	if config == nil {
+		return nil, errors.New("akamai: the configuration of the DNS provider is nil")
+	}
+
+	if config.ClientToken == "" || config.ClientSecret == "" || config.AccessToken == "" || config.Host == "" {
+		return nil, errors.New("akamai: some credentials information are missing")
+	}
+
+	edgegrid.Config = edgegrid.Config{
+		Host:         config.Host,
+		ClientToken:  config.ClientToken,
+		ClientSecret: config.ClientSecret,
+		AccessToken:  config.AccessToken,
+		MaxBody:      dns.MaxBody,
+	}
+
+	return &DNSProvider{config: config}, nil
+}
+
+// Present creates a TXT record to fulfill the dns-01 challenge.
+func (d *DNSProvider) Present(domain, token, keyAuth string) error {
+	fqdn, value, _ := acme.DNS01Record(domain, keyAuth)
+
+	zoneName, err := findZoneByFqdn(fqdn)
+	if err != nil {
+		return fmt.Errorf("akamai: could not determine zone for domain: %q: %w", domain, err)
+	}
+
+	zone, err := dns.GetZone(zoneName)
+	if err != nil {
+		return fmt.Errorf("akamai: could not find zone %q: %w", zoneName, err)
+	}
+
+	record := dns.NewTxtRecord()
+	record.SetField("name", acme.UnFqdn(fqdn))
+	record.SetField("ttl", d.config.TTL)
+	record.SetField("target", value)
+	record.SetField("active", true)
+
+	zone.AddRecord(record)
+
+	if err := zone.Save(); err != nil {
+		return fmt.Errorf("akamai: failed to add record: %w", err)
+	}
+
+	return nil
+}
+
+// CleanUp removes the TXT record matching the specified parameters.
+func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
+	fqdn, value, _ := acme.DNS01Record(domain, keyAuth)
+
+	zoneName, err := findZoneByFqdn(fqdn)
+	if err != nil {
+		return fmt.Errorf("akamai: could not determine zone for domain: %q: %w", domain, err)
+	}
+
+	zone, err := dns.GetZone(zoneName)
+	if err != nil {
+		return fmt.Errorf("akamai: could not find zone %q: %w", zoneName, err)
+	}
+
+	records := zone.Zone.Txt
+	for _, record := range records {
+		if record.Name == acme.UnFqdn(fqdn) && record.Target == value {
+			zone.RemoveRecord(record)
+		}
+	}
+
+	if err := zone.Save(); err != nil {
+		return fmt.Errorf("akamai: failed to remove record: %w", err)
+	}
+
+	return nil
+}
+
+// Timeout returns the timeout and interval to use when checking for DNS propagation.
+func (d *DNSProvider) Timeout() (timeout, interval time.Duration) {
+	return d.config.PropagationTimeout, d.config.PollingInterval
+}
+
+func findZoneByFqdn(fqdn string) (string, error) {
+	authZone, err := acme.FindZoneByFqdn(fqdn, acme.RecursiveNameservers)
+	if err != nil {
+		return "", err
+	}
+
+	return acme.UnFqdn(authZone), nil
+}
@@ -39,6 +39,9 @@ type DNSProvider struct {
 	recordIDsMu sync.Mutex
 	config      *Config
 	client      *auroradns.Client
+
+	zonesMu sync.Mutex
+	zones   []auroradns.Zone
 }
 
 // NewDNSProvider returns a DNSProvider instance configured for AuroraDNS.
@@ -145,6 +148,27 @@ func (d *DNSProvider) Present(domain, token, keyAuth string) error {
 	return nil
 }
 
+// PreSolve creates a record with a secret, without waiting for propagation.
+// It allows the orchestrator to batch the ListZones/CreateRecord round trips
+// for every domain in a SAN certificate before waiting once for propagation,
+// implementing acme.PreSolver.
+func (d *DNSProvider) PreSolve(domain, token, keyAuth string) error {
+	return d.Present(domain, token, keyAuth)
+}
+
+// CleanUpAll removes every record created by PreSolve for the given domains,
+// implementing acme.PreSolver.
+func (d *DNSProvider) CleanUpAll(domains []string) error {
+	var lastErr error
+	for _, domain := range domains {
+		if err := d.CleanUp(domain, "", ""); err != nil {
+			lastErr = fmt.Errorf("aurora: could not clean up %q: %v", domain, err)
+		}
+	}
+
+	return lastErr
+}
+
 // CleanUp removes a given record that was generated by Present
 func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
 	fqdn, _, _ := acme.DNS01Record(domain, keyAuth)
@@ -188,7 +212,7 @@ func (d *DNSProvider) Timeout() (timeout, interval time.Duration) {
 }
 
 func (d *DNSProvider) getZoneInformationByName(name string) (auroradns.Zone, error) {
-	zs, _, err := d.client.ListZones()
+	zs, err := d.listZones()
 	if err != nil {
 		return auroradns.Zone{}, err
 	}
@@ -201,3 +225,25 @@ func (d *DNSProvider) getZoneInformationByName(name string) (auroradns.Zone, err
 
 	return auroradns.Zone{}, fmt.Errorf("could not find Zone record")
 }
+
+// listZones returns the account's zones, fetching them once and reusing the
+// result for the lifetime of the DNSProvider. This means a batch of PreSolve
+// calls for a multi-domain SAN certificate shares a single ListZones round
+// trip instead of repeating it per domain.
+func (d *DNSProvider) listZones() ([]auroradns.Zone, error) {
+	d.zonesMu.Lock()
+	defer d.zonesMu.Unlock()
+
+	if d.zones != nil {
+		return d.zones, nil
+	}
+
+	zs, _, err := d.client.ListZones()
+	if err != nil {
+		return nil, err
+	}
+
+	d.zones = zs
+
+	return d.zones, nil
+}
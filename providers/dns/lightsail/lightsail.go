@@ -0,0 +1,124 @@
+// Package lightsail implements a DNS provider for solving the DNS-01 challenge using AWS Lightsail DNS.
+package lightsail
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/lightsail"
+	"github.com/xenolf/lego/old/acme"
+	"github.com/xenolf/lego/platform/config/env"
+)
+
+// Config is used to configure the creation of the DNSProvider
+type Config struct {
+	DNSZone            string
+	PropagationTimeout time.Duration
+	PollingInterval    time.Duration
+	TTL                int
+}
+
+// NewDefaultConfig returns a default configuration for the DNSProvider
+func NewDefaultConfig() *Config {
+	return &Config{
+		TTL:                env.GetOrDefaultInt("LIGHTSAIL_TTL", 30),
+		PropagationTimeout: env.GetOrDefaultSecond("LIGHTSAIL_PROPAGATION_TIMEOUT", acme.DefaultPropagationTimeout),
+		PollingInterval:    env.GetOrDefaultSecond("LIGHTSAIL_POLLING_INTERVAL", acme.DefaultPollingInterval),
+	}
+}
+
+// DNSProvider describes a provider for AWS Lightsail DNS.
+type DNSProvider struct {
+	config *Config
+	client *lightsail.Lightsail
+}
+
+// NewDNSProvider returns a DNSProvider instance configured for Lightsail.
+// Credentials are resolved using the standard AWS SDK credential chain
+// (environment, shared config, EC2/ECS role, etc).
+// The target DNS zone must be passed in the environment variable DNS_ZONE.
+func NewDNSProvider() (*DNSProvider, error) {
+	values, err := env.Get("DNS_ZONE")
+	if err != nil {
+		return nil, fmt.Errorf("lightsail: %w", err)
+	}
+
+	config := NewDefaultConfig()
+	config.DNSZone = values["DNS_ZONE"]
+
+	return NewDNSProviderConfig(config)
+}
+
+// NewDNSProviderConfig return a DNSProvider instance configured for Lightsail.
+func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
+	if config == nil {
+		return nil, errors.New("lightsail: the configuration of the DNS provider is nil")
+	}
+
+	if config.DNSZone == "" {
+		return nil, errors.New("lightsail: DNS zone is missing")
+	}
+
+	sess, err := session.NewSession()
+	if err != nil {
+		return nil, fmt.Errorf("lightsail: %w", err)
+	}
+
+	return &DNSProvider{
+		config: config,
+		client: lightsail.New(sess),
+	}, nil
+}
+
+// Present creates a TXT record to fulfill the dns-01 challenge.
+func (d *DNSProvider) Present(domain, token, keyAuth string) error {
+	fqdn, value, _ := acme.DNS01Record(domain, keyAuth)
+
+	recordName := acme.UnFqdn(fqdn)
+
+	_, err := d.client.CreateDomainEntry(&lightsail.CreateDomainEntryInput{
+		DomainName: &d.config.DNSZone,
+		DomainEntry: &lightsail.DomainEntry{
+			Name:   &recordName,
+			Type:   stringPtr("TXT"),
+			Target: &value,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("lightsail: failed to create domain entry: %w", err)
+	}
+
+	return nil
+}
+
+// CleanUp removes the TXT record matching the specified parameters.
+func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
+	fqdn, value, _ := acme.DNS01Record(domain, keyAuth)
+
+	recordName := acme.UnFqdn(fqdn)
+
+	_, err := d.client.DeleteDomainEntry(&lightsail.DeleteDomainEntryInput{
+		DomainName: &d.config.DNSZone,
+		DomainEntry: &lightsail.DomainEntry{
+			Name:   &recordName,
+			Type:   stringPtr("TXT"),
+			Target: &value,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("lightsail: failed to delete domain entry: %w", err)
+	}
+
+	return nil
+}
+
+// Timeout returns the timeout and interval to use when checking for DNS propagation.
+func (d *DNSProvider) Timeout() (timeout, interval time.Duration) {
+	return d.config.PropagationTimeout, d.config.PollingInterval
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
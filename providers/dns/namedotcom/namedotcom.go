@@ -0,0 +1,150 @@
+// Package namedotcom implements a DNS provider for solving the DNS-01 challenge using Name.com.
+package namedotcom
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/namedotcom/go/namecom"
+	"github.com/xenolf/lego/old/acme"
+	"github.com/xenolf/lego/platform/config/env"
+)
+
+// Config is used to configure the creation of the DNSProvider
+type Config struct {
+	Username           string
+	APIToken           string
+	Server             string
+	PropagationTimeout time.Duration
+	PollingInterval    time.Duration
+	TTL                int
+}
+
+// NewDefaultConfig returns a default configuration for the DNSProvider
+func NewDefaultConfig() *Config {
+	return &Config{
+		TTL:                env.GetOrDefaultInt("NAMECOM_TTL", 300),
+		PropagationTimeout: env.GetOrDefaultSecond("NAMECOM_PROPAGATION_TIMEOUT", acme.DefaultPropagationTimeout),
+		PollingInterval:    env.GetOrDefaultSecond("NAMECOM_POLLING_INTERVAL", acme.DefaultPollingInterval),
+	}
+}
+
+// DNSProvider describes a provider for Name.com.
+type DNSProvider struct {
+	recordIDs   map[string]int64
+	recordIDsMu sync.Mutex
+	config      *Config
+	client      *namecom.NameCom
+}
+
+// NewDNSProvider returns a DNSProvider instance configured for Name.com.
+// Credentials must be passed in the environment variables:
+// NAMECOM_USERNAME and NAMECOM_API_TOKEN.
+func NewDNSProvider() (*DNSProvider, error) {
+	values, err := env.Get("NAMECOM_USERNAME", "NAMECOM_API_TOKEN")
+	if err != nil {
+		return nil, fmt.Errorf("namedotcom: %w", err)
+	}
+
+	config := NewDefaultConfig()
+	config.Username = values["NAMECOM_USERNAME"]
+	config.APIToken = values["NAMECOM_API_TOKEN"]
+	config.Server = env.GetOrFile("NAMECOM_SERVER")
+
+	return NewDNSProviderConfig(config)
+}
+
+// NewDNSProviderConfig return a DNSProvider instance configured for Name.com.
+func NewDNSProviderConfig(config *Config) (*DNSProvider, error) {
+	if config == nil {
+		return nil, errors.New("namedotcom: the configuration of the DNS provider is nil")
+	}
+
+	if config.Username == "" || config.APIToken == "" {
+		return nil, errors.New("namedotcom: some credentials information are missing")
+	}
+
+	client := namecom.New(config.Username, config.APIToken)
+	if config.Server != "" {
+		client.Server = config.Server
+	}
+
+	return &DNSProvider{
+		config:    config,
+		client:    client,
+		recordIDs: make(map[string]int64),
+	}, nil
+}
+
+// Present creates a TXT record to fulfill the dns-01 challenge.
+func (d *DNSProvider) Present(domain, token, keyAuth string) error {
+	fqdn, value, _ := acme.DNS01Record(domain, keyAuth)
+
+	authZone, err := acme.FindZoneByFqdn(fqdn, acme.RecursiveNameservers)
+	if err != nil {
+		return fmt.Errorf("namedotcom: could not determine zone for domain: %q: %w", domain, err)
+	}
+
+	authZone = acme.UnFqdn(authZone)
+	subdomain := fqdn[0 : len(fqdn)-len(authZone)-2]
+
+	request := &namecom.Record{
+		DomainName: authZone,
+		Host:       subdomain,
+		Type:       "TXT",
+		Answer:     value,
+		TTL:        uint32(d.config.TTL),
+	}
+
+	response, err := d.client.CreateRecord(request)
+	if err != nil {
+		return fmt.Errorf("namedotcom: could not create record: %w", err)
+	}
+
+	d.recordIDsMu.Lock()
+	d.recordIDs[fqdn] = response.ID
+	d.recordIDsMu.Unlock()
+
+	return nil
+}
+
+// CleanUp removes the TXT record matching the specified parameters.
+func (d *DNSProvider) CleanUp(domain, token, keyAuth string) error {
+	fqdn, _, _ := acme.DNS01Record(domain, keyAuth)
+
+	authZone, err := acme.FindZoneByFqdn(fqdn, acme.RecursiveNameservers)
+	if err != nil {
+		return fmt.Errorf("namedotcom: could not determine zone for domain: %q: %w", domain, err)
+	}
+
+	d.recordIDsMu.Lock()
+	recordID, ok := d.recordIDs[fqdn]
+	d.recordIDsMu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("namedotcom: unknown record ID for %q", fqdn)
+	}
+
+	request := &namecom.DeleteRecordRequest{
+		DomainName: acme.UnFqdn(authZone),
+		ID:         recordID,
+	}
+
+	_, err = d.client.DeleteRecord(request)
+	if err != nil {
+		return fmt.Errorf("namedotcom: could not delete record: %w", err)
+	}
+
+	d.recordIDsMu.Lock()
+	delete(d.recordIDs, fqdn)
+	d.recordIDsMu.Unlock()
+
+	return nil
+}
+
+// Timeout returns the timeout and interval to use when checking for DNS propagation.
+func (d *DNSProvider) Timeout() (timeout, interval time.Duration) {
+	return d.config.PropagationTimeout, d.config.PollingInterval
+}